@@ -0,0 +1,106 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/filecoin-project/boost/storagemarket/types"
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/crypto"
+	"golang.org/x/xerrors"
+)
+
+// createStorageAsksTableStmt holds the single, most recent signed storage
+// ask for this miner. The row is pinned to id 1 so SetAsk can overwrite it
+// in place instead of letting the table grow by one row per ask update.
+const createStorageAsksTableStmt = `CREATE TABLE IF NOT EXISTS StorageAsks (
+	ID INTEGER PRIMARY KEY CHECK (ID = 1),
+	Price TEXT,
+	VerifiedPrice TEXT,
+	MinPieceSize INTEGER,
+	MaxPieceSize INTEGER,
+	Miner TEXT,
+	Expiry INTEGER,
+	SeqNo INTEGER,
+	Signature BLOB
+)`
+
+// StorageAskDB persists the single, most recent signed storage ask for this miner.
+type StorageAskDB struct {
+	db *sql.DB
+}
+
+func NewStorageAskDB(db *sql.DB) *StorageAskDB {
+	return &StorageAskDB{db: db}
+}
+
+// GetAsk returns the currently persisted ask, or sql.ErrNoRows if SetAsk has
+// never been called.
+func (d *StorageAskDB) GetAsk(ctx context.Context) (*types.SignedStorageAsk, error) {
+	row := d.db.QueryRowContext(ctx, `SELECT Price, VerifiedPrice, MinPieceSize, MaxPieceSize, Miner, Expiry, SeqNo, Signature
+		FROM StorageAsks WHERE ID = 1`)
+
+	var ask types.StorageAsk
+	var minerStr string
+	var price, verifiedPrice string
+	var sig []byte
+	if err := row.Scan(&price, &verifiedPrice, &ask.MinPieceSize, &ask.MaxPieceSize, &minerStr, &ask.Expiry, &ask.SeqNo, &sig); err != nil {
+		return nil, err
+	}
+
+	p, err := abi.TokenAmountFromString(price)
+	if err != nil {
+		return nil, xerrors.Errorf("parsing persisted ask price: %w", err)
+	}
+	vp, err := abi.TokenAmountFromString(verifiedPrice)
+	if err != nil {
+		return nil, xerrors.Errorf("parsing persisted verified ask price: %w", err)
+	}
+	ask.Price = p
+	ask.VerifiedPrice = vp
+
+	miner, err := address.NewFromString(minerStr)
+	if err != nil {
+		return nil, xerrors.Errorf("parsing persisted ask miner address: %w", err)
+	}
+	ask.Miner = miner
+
+	signed := &types.SignedStorageAsk{Ask: &ask}
+	if len(sig) > 0 {
+		s := new(crypto.Signature)
+		if err := s.UnmarshalBinary(sig); err != nil {
+			return nil, xerrors.Errorf("unmarshalling persisted ask signature: %w", err)
+		}
+		signed.Signature = s
+	}
+
+	return signed, nil
+}
+
+// SetAsk persists a new signed ask, replacing whatever was there before.
+func (d *StorageAskDB) SetAsk(ctx context.Context, ask *types.SignedStorageAsk) error {
+	var sig []byte
+	if ask.Signature != nil {
+		b, err := ask.Signature.MarshalBinary()
+		if err != nil {
+			return xerrors.Errorf("marshalling ask signature: %w", err)
+		}
+		sig = b
+	}
+
+	_, err := d.db.ExecContext(ctx, `INSERT INTO StorageAsks
+		(ID, Price, VerifiedPrice, MinPieceSize, MaxPieceSize, Miner, Expiry, SeqNo, Signature)
+		VALUES (1, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (ID) DO UPDATE SET
+			Price = excluded.Price, VerifiedPrice = excluded.VerifiedPrice,
+			MinPieceSize = excluded.MinPieceSize, MaxPieceSize = excluded.MaxPieceSize,
+			Miner = excluded.Miner, Expiry = excluded.Expiry, SeqNo = excluded.SeqNo,
+			Signature = excluded.Signature`,
+		ask.Ask.Price.String(), ask.Ask.VerifiedPrice.String(), ask.Ask.MinPieceSize, ask.Ask.MaxPieceSize,
+		ask.Ask.Miner.String(), ask.Ask.Expiry, ask.Ask.SeqNo, sig)
+	if err != nil {
+		return xerrors.Errorf("persisting ask: %w", err)
+	}
+	return nil
+}
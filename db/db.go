@@ -0,0 +1,12 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// CreateTables creates the database schema, if it doesn't already exist.
+func CreateTables(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, createStorageAsksTableStmt)
+	return err
+}
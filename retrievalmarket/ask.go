@@ -0,0 +1,62 @@
+package retrievalmarket
+
+import (
+	"encoding/json"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/boost/retrievalmarket/types"
+)
+
+// SetRetrievalAsk updates the terms under which this provider serves
+// retrievals: price per byte transferred, price to unseal a piece first,
+// and the payment-interval schedule clients should follow. Like
+// storagemarket's SetAsk, the result is signed with the miner's worker key.
+func (p *Provider) SetRetrievalAsk(ask *types.RetrievalAsk) error {
+	ask.Miner = p.address
+
+	buf, err := json.Marshal(ask)
+	if err != nil {
+		return xerrors.Errorf("marshalling retrieval ask: %w", err)
+	}
+
+	mi, err := p.fullNode.StateMinerInfo(p.ctx, p.address, nil)
+	if err != nil {
+		return xerrors.Errorf("getting miner info: %w", err)
+	}
+	sig, err := p.fullNode.WalletSign(p.ctx, mi.Worker, buf)
+	if err != nil {
+		return xerrors.Errorf("signing retrieval ask: %w", err)
+	}
+
+	p.askLk.Lock()
+	p.ask = &types.SignedRetrievalAsk{Ask: ask, Signature: sig}
+	p.askLk.Unlock()
+
+	return nil
+}
+
+// GetRetrievalAsk returns the ask set via SetRetrievalAsk, or nil if none has
+// been set yet.
+func (p *Provider) GetRetrievalAsk() *types.SignedRetrievalAsk {
+	p.askLk.RLock()
+	defer p.askLk.RUnlock()
+	return p.ask
+}
+
+// ErrProposalBelowAsk is returned when a retrieval deal proposal names terms
+// worse for the provider than its current RetrievalAsk.
+var ErrProposalBelowAsk = xerrors.New("deal proposal terms are below the current retrieval ask")
+
+// checkRetrievalAskTerms rejects a deal proposal that doesn't pay at least
+// as much as ask requires, so a client can't stream a piece for free by
+// simply omitting (or lowballing) the price terms.
+func checkRetrievalAskTerms(ask *types.RetrievalAsk, prop types.DealProposal) error {
+	if prop.PricePerByte.LessThan(ask.PricePerByte) {
+		return xerrors.Errorf("%w: proposed price per byte %s is below ask price %s", ErrProposalBelowAsk, prop.PricePerByte, ask.PricePerByte)
+	}
+	if prop.UnsealPrice.LessThan(ask.UnsealPrice) {
+		return xerrors.Errorf("%w: proposed unseal price %s is below ask unseal price %s", ErrProposalBelowAsk, prop.UnsealPrice, ask.UnsealPrice)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package retrievalmarket
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/boost/retrievalmarket/types"
+)
+
+func testRetrievalAsk() *types.RetrievalAsk {
+	return &types.RetrievalAsk{
+		PricePerByte: abi.NewTokenAmount(10),
+		UnsealPrice:  abi.NewTokenAmount(100),
+	}
+}
+
+func TestCheckRetrievalAskTermsAccepts(t *testing.T) {
+	ask := testRetrievalAsk()
+	prop := types.DealProposal{PricePerByte: abi.NewTokenAmount(10), UnsealPrice: abi.NewTokenAmount(100)}
+	if err := checkRetrievalAskTerms(ask, prop); err != nil {
+		t.Fatalf("expected proposal matching the ask to be accepted, got: %s", err)
+	}
+}
+
+func TestCheckRetrievalAskTermsRejectsLowPricePerByte(t *testing.T) {
+	ask := testRetrievalAsk()
+	prop := types.DealProposal{PricePerByte: abi.NewTokenAmount(9), UnsealPrice: abi.NewTokenAmount(100)}
+	err := checkRetrievalAskTerms(ask, prop)
+	if err == nil {
+		t.Fatal("expected a price-per-byte below the ask to be rejected")
+	}
+	if !xerrors.Is(err, ErrProposalBelowAsk) {
+		t.Fatalf("expected ErrProposalBelowAsk, got: %s", err)
+	}
+}
+
+func TestCheckRetrievalAskTermsRejectsLowUnsealPrice(t *testing.T) {
+	ask := testRetrievalAsk()
+	prop := types.DealProposal{PricePerByte: abi.NewTokenAmount(10), UnsealPrice: abi.NewTokenAmount(99)}
+	err := checkRetrievalAskTerms(ask, prop)
+	if err == nil {
+		t.Fatal("expected an unseal price below the ask to be rejected")
+	}
+	if !xerrors.Is(err, ErrProposalBelowAsk) {
+		t.Fatalf("expected ErrProposalBelowAsk, got: %s", err)
+	}
+}
@@ -0,0 +1,38 @@
+package retrievalmarket
+
+import (
+	"sync"
+
+	"github.com/ipfs/go-cid"
+
+	"github.com/filecoin-project/boost/retrievalmarket/types"
+)
+
+// pieceStore is an in-memory index of PieceCID -> where that piece lives on
+// disk (sector number, offset, length), populated as deals finish sealing.
+// It is intentionally tiny: a provider-local cache used to answer queries
+// without going back to the chain/sealer on every request. Its contents are
+// not persisted and do not survive a restart.
+type pieceStore struct {
+	mu     sync.RWMutex
+	pieces map[cid.Cid]types.PieceLocation
+}
+
+func newPieceStore() *pieceStore {
+	return &pieceStore{pieces: make(map[cid.Cid]types.PieceLocation)}
+}
+
+// RegisterSealedPiece records where a newly-sealed piece can be read back
+// from. Called once a storage deal reaches its final, sealed checkpoint.
+func (ps *pieceStore) RegisterSealedPiece(pieceCID cid.Cid, loc types.PieceLocation) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.pieces[pieceCID] = loc
+}
+
+func (ps *pieceStore) Get(pieceCID cid.Cid) (types.PieceLocation, bool) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	loc, ok := ps.pieces[pieceCID]
+	return loc, ok
+}
@@ -0,0 +1,175 @@
+// Package retrievalmarket serves pieces that boost's storagemarket has
+// already sealed back out to retrieval clients, closing the loop that
+// go-fil-markets/lotus provide via their own retrievalmarket package.
+package retrievalmarket
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/lotus/api/v1api"
+	"github.com/google/uuid"
+	"github.com/ipfs/go-cid"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/boost/retrievalmarket/types"
+	"github.com/filecoin-project/boost/storage/sectorblocks"
+	"github.com/filecoin-project/boost/transport"
+)
+
+var log = logging.Logger("boost-retrieval-provider")
+
+// DealProposalProtocol is the libp2p protocol retrieval clients use to open
+// a deal with this provider.
+const DealProposalProtocol = protocol.ID("/fil/retrieval/boost/1.0.0")
+
+// Provider serves pieces that have been sealed by boost's storage market
+// back out to retrieval clients.
+type Provider struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	address  address.Address
+	fullNode v1api.FullNode
+	secb     *sectorblocks.SectorBlocks
+	host     host.Host
+
+	// transport is used to stream unsealed blocks back to the client.
+	// It's the same pluggable abstraction storagemarket uses for inbound
+	// transfers; retrieval starts out HTTP-only too.
+	transport transport.Transport
+
+	pieces *pieceStore
+
+	askLk sync.RWMutex
+	ask   *types.SignedRetrievalAsk
+
+	dealsLk sync.Mutex
+	deals   map[uuid.UUID]*types.ProviderDealState
+}
+
+func NewProvider(address address.Address, fullNode v1api.FullNode, secb *sectorblocks.SectorBlocks, host host.Host, transport transport.Transport) *Provider {
+	return &Provider{
+		address:   address,
+		fullNode:  fullNode,
+		secb:      secb,
+		host:      host,
+		transport: transport,
+		pieces:    newPieceStore(),
+		deals:     make(map[uuid.UUID]*types.ProviderDealState),
+	}
+}
+
+// Start registers the retrieval deal protocol handler on the libp2p host.
+func (p *Provider) Start(ctx context.Context) error {
+	p.ctx, p.cancel = context.WithCancel(ctx)
+	p.host.SetStreamHandler(DealProposalProtocol, p.handleDealStream)
+	log.Infow("retrieval provider: started")
+	return nil
+}
+
+func (p *Provider) Close() error {
+	p.host.RemoveStreamHandler(DealProposalProtocol)
+	p.cancel()
+	return nil
+}
+
+// RegisterSealedPiece makes a piece that has just finished sealing available
+// for retrieval. Called by the storage market once a deal reaches its final
+// sealed checkpoint.
+func (p *Provider) RegisterSealedPiece(pieceCID cid.Cid, loc types.PieceLocation) {
+	p.pieces.RegisterSealedPiece(pieceCID, loc)
+}
+
+// ListRetrievalDeals returns a snapshot of every retrieval deal this
+// provider currently knows about.
+func (p *Provider) ListRetrievalDeals() []*types.ProviderDealState {
+	p.dealsLk.Lock()
+	defer p.dealsLk.Unlock()
+
+	out := make([]*types.ProviderDealState, 0, len(p.deals))
+	for _, d := range p.deals {
+		cp := *d
+		out = append(out, &cp)
+	}
+	return out
+}
+
+// setDealStatus updates deal's Status under dealsLk, since deal is
+// also reachable from ListRetrievalDeals on another goroutine for as long as
+// the deal is in p.deals.
+func (p *Provider) setDealStatus(deal *types.ProviderDealState, status types.DealStatus) {
+	p.dealsLk.Lock()
+	deal.Status = status
+	p.dealsLk.Unlock()
+}
+
+// handleDealStream accepts a retrieval deal proposal, unseals the requested
+// piece via the Adapter/sectorblocks path and streams it back to the client
+// over the provider's transport.
+func (p *Provider) handleDealStream(s network.Stream) {
+	defer s.Close() // nolint:errcheck
+
+	var dealProp types.DealProposal
+	if err := json.NewDecoder(s).Decode(&dealProp); err != nil {
+		log.Warnw("reading retrieval deal proposal", "err", err)
+		return
+	}
+
+	prop := types.ProviderDealState{
+		ID:       uuid.New(),
+		PieceCID: dealProp.PieceCID,
+		Status:   types.DealStatusNew,
+	}
+
+	p.dealsLk.Lock()
+	p.deals[prop.ID] = &prop
+	p.dealsLk.Unlock()
+
+	signedAsk := p.GetRetrievalAsk()
+	if signedAsk == nil || signedAsk.Ask == nil {
+		p.failDeal(&prop, xerrors.Errorf("no retrieval ask has been set for this miner yet"))
+		return
+	}
+	if err := checkRetrievalAskTerms(signedAsk.Ask, dealProp); err != nil {
+		p.failDeal(&prop, err)
+		return
+	}
+
+	loc, ok := p.pieces.Get(prop.PieceCID)
+	if !ok {
+		p.failDeal(&prop, ErrPieceNotFound)
+		return
+	}
+
+	p.setDealStatus(&prop, types.DealStatusUnsealing)
+	reader, err := p.secb.UnsealSector(p.ctx, loc.SectorNumber, loc.Offset, loc.Length)
+	if err != nil {
+		p.failDeal(&prop, xerrors.Errorf("unsealing piece: %w", err))
+		return
+	}
+	defer reader.Close() // nolint:errcheck
+
+	p.setDealStatus(&prop, types.DealStatusOngoing)
+	n, err := p.transport.Serve(p.ctx, s, reader)
+	if err != nil {
+		p.failDeal(&prop, xerrors.Errorf("streaming piece: %w", err))
+		return
+	}
+
+	p.dealsLk.Lock()
+	prop.TotalSent = n
+	prop.Status = types.DealStatusCompleted
+	p.dealsLk.Unlock()
+}
+
+func (p *Provider) failDeal(deal *types.ProviderDealState, cause error) {
+	log.Warnw("retrieval deal failed", "id", deal.ID, "err", cause)
+	p.setDealStatus(deal, types.DealStatusErrored)
+}
@@ -0,0 +1,56 @@
+package retrievalmarket
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/boost/retrievalmarket/types"
+)
+
+// ErrPieceNotFound is returned when a query asks about a piece this provider
+// doesn't have sealed anywhere.
+var ErrPieceNotFound = xerrors.New("piece not found")
+
+// HandleQuery answers a retrieval query for a piece with a signed quote
+// derived from the current retrieval ask. Clients are expected to call this
+// before proposing a retrieval deal, so they know what they'll be charged.
+func (p *Provider) HandleQuery(ctx context.Context, pieceCID cid.Cid) (*types.QueryResponse, error) {
+	loc, ok := p.pieces.Get(pieceCID)
+	if !ok {
+		return nil, ErrPieceNotFound
+	}
+
+	signedAsk := p.GetRetrievalAsk()
+	if signedAsk == nil {
+		return nil, xerrors.Errorf("no retrieval ask has been set for this miner yet")
+	}
+	ask := signedAsk.Ask
+
+	resp := &types.QueryResponse{
+		PieceCID:                pieceCID,
+		Size:                    loc.Length,
+		PricePerByte:            ask.PricePerByte,
+		UnsealPrice:             ask.UnsealPrice,
+		PaymentInterval:         ask.PaymentInterval,
+		PaymentIntervalIncrease: ask.PaymentIntervalIncrease,
+	}
+
+	buf, err := json.Marshal(resp)
+	if err != nil {
+		return nil, xerrors.Errorf("marshalling query response: %w", err)
+	}
+	mi, err := p.fullNode.StateMinerInfo(ctx, p.address, nil)
+	if err != nil {
+		return nil, xerrors.Errorf("getting miner info: %w", err)
+	}
+	sig, err := p.fullNode.WalletSign(ctx, mi.Worker, buf)
+	if err != nil {
+		return nil, xerrors.Errorf("signing query response: %w", err)
+	}
+	resp.Signature = sig
+
+	return resp, nil
+}
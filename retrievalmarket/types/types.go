@@ -0,0 +1,76 @@
+package types
+
+import (
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/crypto"
+	"github.com/google/uuid"
+	"github.com/ipfs/go-cid"
+)
+
+// RetrievalAsk describes the price a provider charges to retrieve data back
+// from pieces it has already sealed, mirroring storagemarket's StorageAsk.
+type RetrievalAsk struct {
+	Miner                   address.Address
+	PricePerByte            abi.TokenAmount
+	UnsealPrice             abi.TokenAmount
+	PaymentInterval         uint64
+	PaymentIntervalIncrease uint64
+}
+
+// DealProposal is sent by a retrieval client over DealProposalProtocol to
+// open a deal for a piece, naming the price terms it agrees to pay - which
+// must be at least as good for the provider as its current RetrievalAsk.
+type DealProposal struct {
+	PieceCID                cid.Cid
+	PricePerByte            abi.TokenAmount
+	UnsealPrice             abi.TokenAmount
+	PaymentInterval         uint64
+	PaymentIntervalIncrease uint64
+}
+
+// SignedRetrievalAsk is a RetrievalAsk signed with the miner's worker key.
+type SignedRetrievalAsk struct {
+	Ask       *RetrievalAsk
+	Signature *crypto.Signature
+}
+
+// PieceLocation is where a sealed piece can be read back from: which sector,
+// at what offset, and for how long.
+type PieceLocation struct {
+	SectorNumber abi.SectorNumber
+	Offset       abi.PaddedPieceSize
+	Length       abi.PaddedPieceSize
+}
+
+// QueryResponse is the signed response to a retrieval query for a given
+// PieceCID, quoting the price the client will pay for this retrieval.
+type QueryResponse struct {
+	PieceCID                cid.Cid
+	Size                    abi.PaddedPieceSize
+	PricePerByte            abi.TokenAmount
+	UnsealPrice             abi.TokenAmount
+	PaymentInterval         uint64
+	PaymentIntervalIncrease uint64
+	Signature               *crypto.Signature
+}
+
+// DealStatus is the status of a retrieval deal on the provider side.
+type DealStatus string
+
+const (
+	DealStatusNew       DealStatus = "New"
+	DealStatusUnsealing DealStatus = "Unsealing"
+	DealStatusOngoing   DealStatus = "Ongoing"
+	DealStatusCompleted DealStatus = "Completed"
+	DealStatusErrored   DealStatus = "Errored"
+)
+
+// ProviderDealState tracks an in-flight retrieval deal.
+type ProviderDealState struct {
+	ID            uuid.UUID
+	PieceCID      cid.Cid
+	TotalSent     uint64
+	FundsReceived abi.TokenAmount
+	Status        DealStatus
+}
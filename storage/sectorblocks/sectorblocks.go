@@ -0,0 +1,54 @@
+// Package sectorblocks bridges boost's storage/retrieval markets to the
+// miner's sealing subsystem: adding pieces to sectors and reading them back.
+package sectorblocks
+
+import (
+	"context"
+	"io"
+
+	"github.com/filecoin-project/go-fil-markets/storagemarket"
+	"github.com/filecoin-project/go-state-types/abi"
+)
+
+// SectorBlocks hands pieces off to, and reads them back from, the miner's
+// sealing subsystem.
+type SectorBlocks struct {
+	sealer Sealer
+}
+
+// Sealer is the subset of the miner's sealing subsystem boost depends on.
+// It is implemented by the lotus-miner storage manager in production.
+type Sealer interface {
+	AddPiece(ctx context.Context, size abi.PaddedPieceSize, pieceFile string, dealProposal storagemarket.ClientDealProposal) (abi.SectorNumber, abi.PaddedPieceSize, error)
+	AddPieceToUpgrade(ctx context.Context, size abi.PaddedPieceSize, pieceFile string, dealProposal storagemarket.ClientDealProposal, sectorNumber abi.SectorNumber) (abi.SectorNumber, abi.PaddedPieceSize, error)
+	UnsealSector(ctx context.Context, sectorNumber abi.SectorNumber, offset, length abi.PaddedPieceSize) (io.ReadCloser, error)
+	// SectorSealed reports whether sectorNumber has finished sealing (or
+	// ReplicaUpdate, for a snap-deal upgrade) and is ready to be proven.
+	SectorSealed(ctx context.Context, sectorNumber abi.SectorNumber) (bool, error)
+}
+
+func NewSectorBlocks(sealer Sealer) *SectorBlocks {
+	return &SectorBlocks{sealer: sealer}
+}
+
+// AddPiece adds a new piece to a freshly allocated sector.
+func (sb *SectorBlocks) AddPiece(ctx context.Context, size abi.PaddedPieceSize, pieceFile string, dealProposal storagemarket.ClientDealProposal) (abi.SectorNumber, abi.PaddedPieceSize, error) {
+	return sb.sealer.AddPiece(ctx, size, pieceFile, dealProposal)
+}
+
+// AddPieceToUpgrade hands the piece off to the sealer's ReplicaUpdate
+// pipeline, upgrading the named committed-capacity sector in place instead
+// of sealing a brand new one.
+func (sb *SectorBlocks) AddPieceToUpgrade(ctx context.Context, size abi.PaddedPieceSize, pieceFile string, dealProposal storagemarket.ClientDealProposal, sectorNumber abi.SectorNumber) (abi.SectorNumber, abi.PaddedPieceSize, error) {
+	return sb.sealer.AddPieceToUpgrade(ctx, size, pieceFile, dealProposal, sectorNumber)
+}
+
+// UnsealSector reads back a range of a previously sealed piece.
+func (sb *SectorBlocks) UnsealSector(ctx context.Context, sectorNumber abi.SectorNumber, offset, length abi.PaddedPieceSize) (io.ReadCloser, error) {
+	return sb.sealer.UnsealSector(ctx, sectorNumber, offset, length)
+}
+
+// SectorSealed reports whether sectorNumber has finished sealing.
+func (sb *SectorBlocks) SectorSealed(ctx context.Context, sectorNumber abi.SectorNumber) (bool, error) {
+	return sb.sealer.SectorSealed(ctx, sectorNumber)
+}
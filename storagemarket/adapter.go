@@ -0,0 +1,67 @@
+package storagemarket
+
+import (
+	"context"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/lotus/api/v1api"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/boost/storage/sectorblocks"
+	"github.com/filecoin-project/boost/storagemarket/types"
+)
+
+// sealingPollInterval is how often WaitForSealing checks back in on a
+// sector that hasn't finished sealing yet.
+const sealingPollInterval = 30 * time.Second
+
+// Adapter bridges the storage market to the rest of the miner: chain state
+// via FullNode, and the sealing pipeline via secb.
+type Adapter struct {
+	FullNode v1api.FullNode
+	secb     *sectorblocks.SectorBlocks
+
+	maxDealCollateralMultiplier uint64
+}
+
+// AddPiece hands a verified deal's data off to the sealer, either as a new
+// piece in a fresh sector or, if the deal was chosen for a snap-deal
+// upgrade, into an existing CC sector via ReplicaUpdate.
+func (a *Adapter) AddPiece(ctx context.Context, deal *types.ProviderDealState) (abi.SectorNumber, abi.PaddedPieceSize, error) {
+	if deal.SectorUpgrade {
+		return a.secb.AddPieceToUpgrade(ctx, deal.PieceSize, deal.InboundFilePath, deal.ClientDealProposal, deal.UpgradeSectorNumber)
+	}
+
+	sectorNumber, offset, err := a.secb.AddPiece(ctx, deal.PieceSize, deal.InboundFilePath, deal.ClientDealProposal)
+	if err != nil {
+		return 0, 0, xerrors.Errorf("adding piece: %w", err)
+	}
+	return sectorNumber, offset, nil
+}
+
+// WaitForSealing blocks until sectorNumber finishes sealing (or ctx is
+// cancelled), polling the sealer every sealingPollInterval. Sealing is fully
+// automatic once a piece has been added, so unlike handleAccepted waiting on
+// ImportOfflineDealData this doesn't need to park the deal and wait for an
+// external call back in - it just blocks the deal's goroutine.
+func (a *Adapter) WaitForSealing(ctx context.Context, sectorNumber abi.SectorNumber) error {
+	ticker := time.NewTicker(sealingPollInterval)
+	defer ticker.Stop()
+
+	for {
+		sealed, err := a.secb.SectorSealed(ctx, sectorNumber)
+		if err != nil {
+			return xerrors.Errorf("checking status of sector %d: %w", sectorNumber, err)
+		}
+		if sealed {
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
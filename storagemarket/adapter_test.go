@@ -0,0 +1,74 @@
+package storagemarket
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/filecoin-project/go-fil-markets/storagemarket"
+	"github.com/filecoin-project/go-state-types/abi"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/boost/storage/sectorblocks"
+)
+
+// fakeSealer is a minimal sectorblocks.Sealer for exercising Adapter without
+// a real sealing pipeline.
+type fakeSealer struct {
+	sealedAfter int
+	sealedCalls int
+	sealedErr   error
+}
+
+func (f *fakeSealer) AddPiece(ctx context.Context, size abi.PaddedPieceSize, pieceFile string, dealProposal storagemarket.ClientDealProposal) (abi.SectorNumber, abi.PaddedPieceSize, error) {
+	return 0, 0, nil
+}
+
+func (f *fakeSealer) AddPieceToUpgrade(ctx context.Context, size abi.PaddedPieceSize, pieceFile string, dealProposal storagemarket.ClientDealProposal, sectorNumber abi.SectorNumber) (abi.SectorNumber, abi.PaddedPieceSize, error) {
+	return 0, 0, nil
+}
+
+func (f *fakeSealer) UnsealSector(ctx context.Context, sectorNumber abi.SectorNumber, offset, length abi.PaddedPieceSize) (io.ReadCloser, error) {
+	return nil, nil
+}
+
+func (f *fakeSealer) SectorSealed(ctx context.Context, sectorNumber abi.SectorNumber) (bool, error) {
+	f.sealedCalls++
+	if f.sealedErr != nil {
+		return false, f.sealedErr
+	}
+	return f.sealedCalls > f.sealedAfter, nil
+}
+
+func TestWaitForSealingReturnsOnceSealed(t *testing.T) {
+	fs := &fakeSealer{sealedAfter: 0}
+	a := &Adapter{secb: sectorblocks.NewSectorBlocks(fs)}
+
+	if err := a.WaitForSealing(context.Background(), abi.SectorNumber(1)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestWaitForSealingPropagatesSealerError(t *testing.T) {
+	fs := &fakeSealer{sealedErr: errors.New("sealer unavailable")}
+	a := &Adapter{secb: sectorblocks.NewSectorBlocks(fs)}
+
+	err := a.WaitForSealing(context.Background(), abi.SectorNumber(1))
+	if err == nil {
+		t.Fatal("expected sealer error to be propagated")
+	}
+}
+
+func TestWaitForSealingStopsOnContextCancel(t *testing.T) {
+	fs := &fakeSealer{sealedAfter: 1000000} // never reports sealed
+	a := &Adapter{secb: sectorblocks.NewSectorBlocks(fs)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := a.WaitForSealing(ctx, abi.SectorNumber(1))
+	if !xerrors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got: %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package storagemarket
+
+import (
+	"encoding/json"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/crypto"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/boost/storagemarket/types"
+)
+
+// AskOption customizes a new ask before it's signed and persisted in SetAsk.
+type AskOption func(*types.StorageAsk)
+
+// MinPieceSize sets the minimum piece size the provider will accept.
+func MinPieceSize(size abi.PaddedPieceSize) AskOption {
+	return func(ask *types.StorageAsk) {
+		ask.MinPieceSize = size
+	}
+}
+
+// MaxPieceSize sets the maximum piece size the provider will accept.
+func MaxPieceSize(size abi.PaddedPieceSize) AskOption {
+	return func(ask *types.StorageAsk) {
+		ask.MaxPieceSize = size
+	}
+}
+
+// SetAsk updates the terms under which this provider accepts deals: the
+// price per epoch for unverified and verified deals, and how long the new
+// ask should remain valid for. The ask is signed with the miner's worker
+// key and persisted so it survives a restart.
+func (p *Provider) SetAsk(price, verifiedPrice abi.TokenAmount, duration abi.ChainEpoch, options ...AskOption) error {
+	head, err := p.adapter.FullNode.ChainHead(p.ctx)
+	if err != nil {
+		return xerrors.Errorf("getting chain head to compute ask expiry: %w", err)
+	}
+
+	prev, err := p.GetAsk()
+	if err != nil {
+		return xerrors.Errorf("getting previous ask: %w", err)
+	}
+	var seqno uint64
+	minPieceSize := abi.PaddedPieceSize(0)
+	maxPieceSize := abi.PaddedPieceSize(64 * 1024 * 1024 * 1024)
+	if prev != nil && prev.Ask != nil {
+		seqno = prev.Ask.SeqNo + 1
+		minPieceSize = prev.Ask.MinPieceSize
+		maxPieceSize = prev.Ask.MaxPieceSize
+	}
+
+	ask := &types.StorageAsk{
+		Price:         price,
+		VerifiedPrice: verifiedPrice,
+		MinPieceSize:  minPieceSize,
+		MaxPieceSize:  maxPieceSize,
+		Miner:         p.Address,
+		Expiry:        head.Height() + duration,
+		SeqNo:         seqno,
+	}
+	for _, opt := range options {
+		opt(ask)
+	}
+
+	sig, err := p.signAsk(ask)
+	if err != nil {
+		return xerrors.Errorf("signing ask: %w", err)
+	}
+
+	signed := &types.SignedStorageAsk{Ask: ask, Signature: sig}
+	if err := p.askDB.SetAsk(p.ctx, signed); err != nil {
+		return xerrors.Errorf("persisting ask: %w", err)
+	}
+
+	return nil
+}
+
+// signAsk signs the JSON-marshalled ask with the miner's worker key.
+func (p *Provider) signAsk(ask *types.StorageAsk) (*crypto.Signature, error) {
+	mi, err := p.adapter.FullNode.StateMinerInfo(p.ctx, p.Address, nil)
+	if err != nil {
+		return nil, xerrors.Errorf("getting miner info: %w", err)
+	}
+
+	buf, err := json.Marshal(ask)
+	if err != nil {
+		return nil, xerrors.Errorf("marshalling ask: %w", err)
+	}
+
+	return p.adapter.FullNode.WalletSign(p.ctx, mi.Worker, buf)
+}
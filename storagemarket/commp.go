@@ -0,0 +1,92 @@
+package storagemarket
+
+import (
+	"io"
+	"os"
+
+	commpWriter "github.com/filecoin-project/go-commp-utils/writer"
+	padreader "github.com/filecoin-project/go-padreader"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/boost/storagemarket/types"
+)
+
+// ErrCommPMismatch is surfaced on a deal's update channel when the computed
+// CommP of the received data doesn't match what the client proposed.
+var ErrCommPMismatch = xerrors.New("commP of received data does not match proposal")
+
+// verifyCommP streams the data at deal.InboundFilePath through a commP
+// writer, padding it the same way lotus client.go does (up to the next
+// power-of-two piece size), and compares the result against what the client
+// proposed. It's throttled by Provider.commPThrottle so that several large
+// deals running at once don't thrash the CPU/disk.
+func (p *Provider) verifyCommP(deal *types.ProviderDealState) error {
+	select {
+	case p.commPThrottle <- struct{}{}:
+	case <-p.ctx.Done():
+		return p.ctx.Err()
+	}
+	defer func() { <-p.commPThrottle }()
+
+	f, err := os.Open(deal.InboundFilePath)
+	if err != nil {
+		return xerrors.Errorf("opening inbound file for commP: %w", err)
+	}
+	defer f.Close() // nolint:errcheck
+
+	fi, err := f.Stat()
+	if err != nil {
+		return xerrors.Errorf("statting inbound file for commP: %w", err)
+	}
+
+	proposedPieceSize := deal.ClientDealProposal.Proposal.PieceSize
+	paddedReader, paddedSize := padreader.New(f, uint64(fi.Size()))
+	if err := checkPieceSize(abi.UnpaddedPieceSize(paddedSize).Padded(), proposedPieceSize); err != nil {
+		return err
+	}
+
+	w := &commpWriter.Writer{}
+	if _, err := io.Copy(w, paddedReader); err != nil {
+		return xerrors.Errorf("writing data into commP writer: %w", err)
+	}
+
+	dataCIDSize, err := w.Sum()
+	if err != nil {
+		return xerrors.Errorf("computing commP: %w", err)
+	}
+
+	if err := checkPieceSize(dataCIDSize.PieceSize, proposedPieceSize); err != nil {
+		return err
+	}
+	if err := checkPieceCID(dataCIDSize.PieceCID, deal.ClientDealProposal.Proposal.PieceCID); err != nil {
+		return err
+	}
+
+	deal.PieceCID = dataCIDSize.PieceCID
+	deal.PieceSize = dataCIDSize.PieceSize
+	if err := p.dealsDB.Update(p.ctx, deal); err != nil {
+		return xerrors.Errorf("persisting computed commP: %w", err)
+	}
+
+	return nil
+}
+
+// checkPieceSize reports ErrCommPMismatch if got doesn't match the piece
+// size the client proposed.
+func checkPieceSize(got, proposed abi.PaddedPieceSize) error {
+	if got != proposed {
+		return xerrors.Errorf("%w: piece size %d does not match proposed piece size %d", ErrCommPMismatch, got, proposed)
+	}
+	return nil
+}
+
+// checkPieceCID reports ErrCommPMismatch if got doesn't match the piece CID
+// the client proposed.
+func checkPieceCID(got, proposed cid.Cid) error {
+	if !got.Equals(proposed) {
+		return xerrors.Errorf("%w: piece CID %s does not match proposed piece CID %s", ErrCommPMismatch, got, proposed)
+	}
+	return nil
+}
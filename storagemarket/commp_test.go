@@ -0,0 +1,46 @@
+package storagemarket
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
+)
+
+func TestCheckPieceSize(t *testing.T) {
+	if err := checkPieceSize(abi.PaddedPieceSize(1024), abi.PaddedPieceSize(1024)); err != nil {
+		t.Fatalf("expected matching piece sizes to pass, got: %s", err)
+	}
+
+	err := checkPieceSize(abi.PaddedPieceSize(1024), abi.PaddedPieceSize(2048))
+	if err == nil {
+		t.Fatal("expected mismatched piece sizes to be rejected")
+	}
+	if !xerrors.Is(err, ErrCommPMismatch) {
+		t.Fatalf("expected ErrCommPMismatch, got: %s", err)
+	}
+}
+
+func TestCheckPieceCID(t *testing.T) {
+	a, err := cid.Parse("bafkqaaa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := cid.Parse("bafkqaaia")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := checkPieceCID(a, a); err != nil {
+		t.Fatalf("expected matching CIDs to pass, got: %s", err)
+	}
+
+	err = checkPieceCID(a, b)
+	if err == nil {
+		t.Fatal("expected mismatched CIDs to be rejected")
+	}
+	if !xerrors.Is(err, ErrCommPMismatch) {
+		t.Fatalf("expected ErrCommPMismatch, got: %s", err)
+	}
+}
@@ -0,0 +1,178 @@
+package storagemarket
+
+import (
+	"golang.org/x/xerrors"
+
+	rmtypes "github.com/filecoin-project/boost/retrievalmarket/types"
+	"github.com/filecoin-project/boost/storagemarket/types"
+	"github.com/filecoin-project/boost/storagemarket/types/dealcheckpoints"
+)
+
+// errAwaitingExternalEvent is returned by a checkpoint handler to mean "this
+// deal cannot advance on its own right now" - e.g. an offline deal sitting
+// at Accepted waiting on ImportOfflineDealData. It is not a failure: the
+// deal stays at its current checkpoint and some other call into the
+// provider is expected to move it forward later.
+var errAwaitingExternalEvent = xerrors.New("deal is awaiting an external event")
+
+// checkpointHandlers maps each non-terminal checkpoint to the function that
+// performs the work for that stage and returns the checkpoint to advance to.
+var checkpointHandlers = map[dealcheckpoints.Checkpoint]func(*Provider, *types.ProviderDealState) (dealcheckpoints.Checkpoint, error){
+	dealcheckpoints.Accepted:            (*Provider).handleAccepted,
+	dealcheckpoints.Transferring:        (*Provider).handleTransferring,
+	dealcheckpoints.Transferred:         (*Provider).handleTransferred,
+	dealcheckpoints.CommPComputed:       (*Provider).handleCommPComputed,
+	dealcheckpoints.Published:           (*Provider).handlePublished,
+	dealcheckpoints.PublishConfirmed:    (*Provider).handlePublishConfirmed,
+	dealcheckpoints.AddedToPiecestore:   (*Provider).handleAddedToPiecestore,
+	dealcheckpoints.Sealing:             (*Provider).handleSealing,
+	dealcheckpoints.IndexedAndAnnounced: (*Provider).handleIndexedAndAnnounced,
+}
+
+// runDeal drives deal through checkpointHandlers, persisting and publishing
+// an update after every transition, until it either reaches a terminal
+// checkpoint or a handler reports it's waiting on something external.
+func (p *Provider) runDeal(deal *types.ProviderDealState) {
+	for {
+		if deal.Checkpoint.IsTerminal() {
+			return
+		}
+
+		handler, ok := checkpointHandlers[deal.Checkpoint]
+		if !ok {
+			p.failDeal(deal, xerrors.Errorf("no handler for checkpoint %s", deal.Checkpoint))
+			return
+		}
+
+		next, err := handler(p, deal)
+		if err != nil {
+			if xerrors.Is(err, errAwaitingExternalEvent) {
+				return
+			}
+			p.failDeal(deal, err)
+			return
+		}
+
+		deal.Checkpoint = next
+		if err := p.dealsDB.Update(p.ctx, deal); err != nil {
+			log.Errorw("persisting deal checkpoint", "id", deal.DealUuid, "checkpoint", next, "err", err)
+		}
+		p.dealHandlers.notifyUpdated(deal.DealUuid, deal)
+	}
+}
+
+func (p *Provider) failDeal(deal *types.ProviderDealState, cause error) {
+	deal.Checkpoint = dealcheckpoints.Failed
+	deal.FailureReason = cause.Error()
+	if err := p.dealsDB.Update(p.ctx, deal); err != nil {
+		log.Errorw("persisting failed deal", "id", deal.DealUuid, "err", err)
+	}
+	p.dealHandlers.notifyUpdated(deal.DealUuid, deal)
+}
+
+func (p *Provider) handleAccepted(deal *types.ProviderDealState) (dealcheckpoints.Checkpoint, error) {
+	if deal.IsOffline {
+		// waits for Provider.ImportOfflineDealData to move it to Transferred.
+		return dealcheckpoints.Accepted, errAwaitingExternalEvent
+	}
+	return dealcheckpoints.Transferring, nil
+}
+
+func (p *Provider) handleTransferring(deal *types.ProviderDealState) (dealcheckpoints.Checkpoint, error) {
+	t, err := p.Transports.TransportFor(deal.Transfer.Type)
+	if err != nil {
+		return dealcheckpoints.Transferring, xerrors.Errorf("transferring deal data: %w", err)
+	}
+
+	n, err := t.Execute(p.ctx, deal.InboundFilePath, deal.Transfer.Params)
+	// record bytes moved so far the same way regardless of which transport
+	// handled the transfer.
+	p.transfers.setBytes(deal.DealUuid, n)
+	if err != nil {
+		return dealcheckpoints.Transferring, xerrors.Errorf("transferring deal data: %w", err)
+	}
+	return dealcheckpoints.Transferred, nil
+}
+
+func (p *Provider) handleTransferred(deal *types.ProviderDealState) (dealcheckpoints.Checkpoint, error) {
+	// a resumed deal that already has its PieceCID persisted already passed
+	// this stage before the provider restarted - don't re-hash the data.
+	if deal.PieceCID.Defined() {
+		return dealcheckpoints.CommPComputed, nil
+	}
+	if err := p.verifyCommP(deal); err != nil {
+		return dealcheckpoints.Transferred, err
+	}
+	return dealcheckpoints.CommPComputed, nil
+}
+
+func (p *Provider) handleCommPComputed(deal *types.ProviderDealState) (dealcheckpoints.Checkpoint, error) {
+	if err := p.dealPublisher.Publish(p.ctx, deal); err != nil {
+		return dealcheckpoints.CommPComputed, xerrors.Errorf("publishing deal: %w", err)
+	}
+	return dealcheckpoints.Published, nil
+}
+
+func (p *Provider) handlePublished(deal *types.ProviderDealState) (dealcheckpoints.Checkpoint, error) {
+	if err := p.dealPublisher.WaitForPublish(p.ctx, deal); err != nil {
+		return dealcheckpoints.Published, xerrors.Errorf("waiting for publish confirmation: %w", err)
+	}
+	return dealcheckpoints.PublishConfirmed, nil
+}
+
+func (p *Provider) handlePublishConfirmed(deal *types.ProviderDealState) (dealcheckpoints.Checkpoint, error) {
+	if p.config.EnableSnapDeals && p.config.SnapDealsSectorSelector != nil {
+		sectorNumber, err := p.config.SnapDealsSectorSelector(p.ctx, deal.PieceSize)
+		if err != nil {
+			log.Infow("no snap-deal sector available, falling back to a fresh sector", "id", deal.DealUuid, "err", err)
+		} else {
+			deal.SectorUpgrade = true
+			deal.UpgradeSectorNumber = sectorNumber
+		}
+	}
+
+	sectorNumber, offset, err := p.adapter.AddPiece(p.ctx, deal)
+	if err != nil && deal.SectorUpgrade {
+		// the selected CC sector can fail the upgrade for reasons that have
+		// nothing to do with the deal itself (it was consumed by another
+		// upgrade, its proof got invalidated, etc). Rather than failing the
+		// whole deal over that, fall back once to a fresh sector the same
+		// way a non-upgrade deal would be sealed.
+		log.Infow("snap-deal upgrade failed, falling back to a fresh sector", "id", deal.DealUuid, "sector", deal.UpgradeSectorNumber, "err", err)
+		deal.SectorUpgrade = false
+		deal.UpgradeSectorNumber = 0
+		sectorNumber, offset, err = p.adapter.AddPiece(p.ctx, deal)
+	}
+	if err != nil {
+		return dealcheckpoints.PublishConfirmed, xerrors.Errorf("adding piece to sector: %w", err)
+	}
+	deal.SectorNumber = sectorNumber
+	deal.SectorOffset = offset
+	return dealcheckpoints.AddedToPiecestore, nil
+}
+
+func (p *Provider) handleAddedToPiecestore(deal *types.ProviderDealState) (dealcheckpoints.Checkpoint, error) {
+	return dealcheckpoints.Sealing, nil
+}
+
+func (p *Provider) handleSealing(deal *types.ProviderDealState) (dealcheckpoints.Checkpoint, error) {
+	// sealing (or, for a snap-deal upgrade, ReplicaUpdate) runs on the
+	// miner's sealing pipeline and can take hours - block here, the same
+	// way handlePublished blocks on WaitForPublish, until it's actually
+	// done instead of advancing the moment AddPiece merely started it.
+	if err := p.adapter.WaitForSealing(p.ctx, deal.SectorNumber); err != nil {
+		return dealcheckpoints.Sealing, xerrors.Errorf("waiting for sector %d to finish sealing: %w", deal.SectorNumber, err)
+	}
+	return dealcheckpoints.IndexedAndAnnounced, nil
+}
+
+func (p *Provider) handleIndexedAndAnnounced(deal *types.ProviderDealState) (dealcheckpoints.Checkpoint, error) {
+	if p.retrievalProvider != nil {
+		p.retrievalProvider.RegisterSealedPiece(deal.PieceCID, rmtypes.PieceLocation{
+			SectorNumber: deal.SectorNumber,
+			Offset:       deal.SectorOffset,
+			Length:       deal.PieceSize,
+		})
+	}
+	return dealcheckpoints.Complete, nil
+}
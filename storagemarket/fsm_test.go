@@ -0,0 +1,48 @@
+package storagemarket
+
+import (
+	"testing"
+
+	"github.com/ipfs/go-cid"
+
+	"github.com/filecoin-project/boost/storagemarket/types"
+	"github.com/filecoin-project/boost/storagemarket/types/dealcheckpoints"
+)
+
+func TestHandleTransferredSkipsCommPWhenAlreadyComputed(t *testing.T) {
+	p := &Provider{}
+	deal := &types.ProviderDealState{
+		PieceCID: mustParseCID(t, "bafkqaaa"),
+	}
+
+	// verifyCommP would panic/nil-pointer on a zero-value Provider (no ctx,
+	// no commPThrottle, no dealsDB) - reaching CommPComputed without
+	// panicking proves the short-circuit fired instead of recomputing.
+	cp, err := p.handleTransferred(deal)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if cp != dealcheckpoints.CommPComputed {
+		t.Fatalf("got checkpoint %s, want %s", cp, dealcheckpoints.CommPComputed)
+	}
+}
+
+func TestHandleAddedToPiecestoreAdvancesToSealing(t *testing.T) {
+	p := &Provider{}
+	cp, err := p.handleAddedToPiecestore(&types.ProviderDealState{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if cp != dealcheckpoints.Sealing {
+		t.Fatalf("got checkpoint %s, want %s", cp, dealcheckpoints.Sealing)
+	}
+}
+
+func mustParseCID(t *testing.T, s string) cid.Cid {
+	t.Helper()
+	c, err := cid.Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
@@ -0,0 +1,71 @@
+package storagemarket
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/filecoin-project/boost/storagemarket/types/dealcheckpoints"
+	"github.com/google/uuid"
+)
+
+// ImportOfflineDealData is called by an operator once the data for an offline
+// deal (one accepted with ClientDealParams.IsOffline set) has been staged
+// somewhere on disk, e.g. copied off a shipped hard drive or downloaded from
+// an HTTP mirror. It copies the CAR file referenced by carPath into the
+// provider's filestore (the file at carPath is left in place), records it against the deal and re-dispatches the
+// deal into the event loop so it goes through CommP verification, publish
+// and sector add like any other deal.
+func (p *Provider) ImportOfflineDealData(ctx context.Context, dealUuid uuid.UUID, carPath string) error {
+	fi, err := os.Stat(carPath)
+	if err != nil {
+		return fmt.Errorf("checking offline deal data at %s: %w", carPath, err)
+	}
+	if fi.IsDir() {
+		return fmt.Errorf("offline deal data path %s is a directory", carPath)
+	}
+	f, err := os.Open(carPath)
+	if err != nil {
+		return fmt.Errorf("opening offline deal data at %s: %w", carPath, err)
+	}
+	defer f.Close() // nolint:errcheck
+
+	deal, err := p.dealsDB.ByID(ctx, dealUuid)
+	if err != nil {
+		return fmt.Errorf("getting deal %s: %w", dealUuid, err)
+	}
+	if !deal.IsOffline || deal.Checkpoint != dealcheckpoints.Accepted {
+		return fmt.Errorf("deal %s is not awaiting a manual import, checkpoint is %s", dealUuid, deal.Checkpoint)
+	}
+
+	store, err := p.fs.CreateTemp()
+	if err != nil {
+		return fmt.Errorf("creating filestore entry for offline deal %s: %w", dealUuid, err)
+	}
+	if _, err := io.Copy(store, f); err != nil {
+		_ = os.Remove(string(store.OsPath()))
+		_ = store.Close()
+		return fmt.Errorf("copying offline deal data for %s into filestore: %w", dealUuid, err)
+	}
+	if err := store.Close(); err != nil {
+		return fmt.Errorf("closing filestore entry for offline deal %s: %w", dealUuid, err)
+	}
+
+	deal.InboundFilePath = string(store.OsPath())
+	deal.Checkpoint = dealcheckpoints.Transferred
+	if err := p.dealsDB.Update(ctx, deal); err != nil {
+		return fmt.Errorf("persisting offline deal data path for %s: %w", dealUuid, err)
+	}
+
+	// dispatch the deal back into the event loop so it proceeds to CommP
+	// verification, publish and sector add exactly like an online deal
+	// whose transfer just completed.
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		p.runDeal(deal)
+	}()
+
+	return nil
+}
@@ -13,12 +13,16 @@ import (
 	"github.com/filecoin-project/boost/db"
 	"github.com/filecoin-project/boost/filestore"
 	"github.com/filecoin-project/boost/fundmanager"
+	"github.com/filecoin-project/boost/retrievalmarket"
 	"github.com/filecoin-project/boost/storage/sectorblocks"
 	"github.com/filecoin-project/boost/storagemanager"
 	"github.com/filecoin-project/boost/storagemarket/types"
+	"github.com/filecoin-project/boost/storagemarket/types/dealcheckpoints"
 	"github.com/filecoin-project/boost/transport"
+	"github.com/filecoin-project/boost/transport/graphsync"
 	"github.com/filecoin-project/boost/transport/httptransport"
 	"github.com/filecoin-project/go-address"
+	datatransfer "github.com/filecoin-project/go-data-transfer"
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/lotus/api/v1api"
 	"github.com/google/uuid"
@@ -31,8 +35,29 @@ var log = logging.Logger("boost-provider")
 
 var ErrDealNotFound = fmt.Errorf("deal not found")
 
+// defaultCommPThrottle is used when Config.CommPThrottle is left unset.
+const defaultCommPThrottle = 4
+
 type Config struct {
 	MaxTransferDuration time.Duration
+	// CommPThrottle bounds how many deals may have their CommP computed at
+	// the same time, so a pile of large deals transferring at once doesn't
+	// saturate CPU/disk. Defaults to 4 if unset.
+	CommPThrottle int
+
+	// EnableSnapDeals lets the provider upgrade validated deals directly
+	// into existing committed-capacity sectors instead of always sealing a
+	// fresh one, using SnapDealsSectorSelector to pick the target sector.
+	EnableSnapDeals bool
+	// SnapDealsSectorSelector picks a CC sector to upgrade for a deal of the
+	// given padded piece size. It's only consulted when EnableSnapDeals is
+	// true, and must return an error if no eligible sector is available.
+	SnapDealsSectorSelector func(ctx context.Context, size abi.PaddedPieceSize) (abi.SectorNumber, error)
+
+	// TransportConfig bounds resource usage per transfer type (e.g. "http",
+	// "graphsync"). A transfer type with no entry runs with transport.Config's
+	// zero value (no limits).
+	TransportConfig map[string]transport.Config
 }
 
 type Provider struct {
@@ -54,21 +79,30 @@ type Provider struct {
 	acceptDealsChan chan acceptDealReq
 	failedDealsChan chan failedDealReq
 
+	// commPThrottle limits how many deals compute their CommP concurrently.
+	commPThrottle chan struct{}
+
 	// Database API
 	db      *sql.DB
 	dealsDB *db.DealsDB
+	askDB   *db.StorageAskDB
 
-	Transport      transport.Transport
+	Transports     *transport.Registry
 	fundManager    *fundmanager.FundManager
 	storageManager *storagemanager.StorageManager
 	dealPublisher  *DealPublisher
 	adapter        *Adapter
-	transfers      *dealTransfers
+
+	// retrievalProvider, if set, is notified of every piece that finishes
+	// sealing so it can be served back to retrieval clients. It's optional:
+	// a storage-only deployment can leave it nil.
+	retrievalProvider *retrievalmarket.Provider
+	transfers         *dealTransfers
 
 	dealHandlers *dealHandlers
 }
 
-func NewProvider(repoRoot string, sqldb *sql.DB, dealsDB *db.DealsDB, fundMgr *fundmanager.FundManager, storageMgr *storagemanager.StorageManager, fullnodeApi v1api.FullNode, dealPublisher *DealPublisher, addr address.Address, secb *sectorblocks.SectorBlocks) (*Provider, error) {
+func NewProvider(repoRoot string, cfg Config, sqldb *sql.DB, dealsDB *db.DealsDB, fundMgr *fundmanager.FundManager, storageMgr *storagemanager.StorageManager, fullnodeApi v1api.FullNode, dealPublisher *DealPublisher, addr address.Address, secb *sectorblocks.SectorBlocks, dtManager datatransfer.Manager) (*Provider, error) {
 	fspath := path.Join(repoRoot, "incoming")
 	err := os.MkdirAll(fspath, os.ModePerm)
 	if err != nil {
@@ -84,18 +118,32 @@ func NewProvider(repoRoot string, sqldb *sql.DB, dealsDB *db.DealsDB, fundMgr *f
 		return nil, err
 	}
 
+	if cfg.MaxTransferDuration == 0 {
+		cfg.MaxTransferDuration = 24 * 3600 * time.Second
+	}
+	if cfg.CommPThrottle == 0 {
+		cfg.CommPThrottle = defaultCommPThrottle
+	}
+
+	transports := transport.NewRegistry(map[string]transport.Transport{
+		"http":      httptransport.New(cfg.TransportConfig["http"]),
+		"graphsync": graphsync.New(dtManager, cfg.TransportConfig["graphsync"]),
+	})
+
 	return &Provider{
-		config:    Config{MaxTransferDuration: 24 * 3600 * time.Second},
+		config:    cfg,
 		Address:   addr,
 		newDealPS: newDealPS,
 		fs:        fs,
 		db:        sqldb,
 		dealsDB:   dealsDB,
+		askDB:     db.NewStorageAskDB(sqldb),
 
 		acceptDealsChan: make(chan acceptDealReq),
 		failedDealsChan: make(chan failedDealReq),
+		commPThrottle:   make(chan struct{}, cfg.CommPThrottle),
 
-		Transport:      httptransport.New(),
+		Transports:     transports,
 		fundManager:    fundMgr,
 		storageManager: storageMgr,
 
@@ -111,6 +159,13 @@ func NewProvider(repoRoot string, sqldb *sql.DB, dealsDB *db.DealsDB, fundMgr *f
 	}, nil
 }
 
+// SetRetrievalProvider wires a retrieval market provider into this storage
+// provider, so that pieces are registered for retrieval as they finish
+// sealing. Must be called before Start.
+func (p *Provider) SetRetrievalProvider(rp *retrievalmarket.Provider) {
+	p.retrievalProvider = rp
+}
+
 func (p *Provider) Deal(ctx context.Context, dealUuid uuid.UUID) (*types.ProviderDealState, error) {
 	deal, err := p.dealsDB.ByID(ctx, dealUuid)
 	if xerrors.Is(err, sql.ErrNoRows) {
@@ -123,19 +178,34 @@ func (p *Provider) NBytesReceived(deal *types.ProviderDealState) uint64 {
 	return p.transfers.getBytes(deal.DealUuid)
 }
 
-func (p *Provider) GetAsk() *types.StorageAsk {
-	return &types.StorageAsk{
-		Price:         abi.NewTokenAmount(1),
-		VerifiedPrice: abi.NewTokenAmount(1),
-		MinPieceSize:  0,
-		MaxPieceSize:  64 * 1024 * 1024 * 1024,
-		Miner:         p.Address,
+// GetAsk returns the last ask set via SetAsk. If SetAsk has never been
+// called, it falls back to a conservative default so the provider doesn't
+// start out rejecting every deal. Any other error reading the ask (e.g. a
+// transient DB failure) is returned rather than masked by that fallback.
+func (p *Provider) GetAsk() (*types.SignedStorageAsk, error) {
+	signed, err := p.askDB.GetAsk(p.ctx)
+	if err != nil {
+		if xerrors.Is(err, sql.ErrNoRows) {
+			return &types.SignedStorageAsk{Ask: &types.StorageAsk{
+				Price:         abi.NewTokenAmount(1),
+				VerifiedPrice: abi.NewTokenAmount(1),
+				MinPieceSize:  0,
+				MaxPieceSize:  64 * 1024 * 1024 * 1024,
+				Miner:         p.Address,
+			}}, nil
+		}
+		return nil, xerrors.Errorf("getting persisted ask: %w", err)
 	}
+	return signed, nil
 }
 
 func (p *Provider) ExecuteDeal(dp *types.ClientDealParams) (pi *api.ProviderDealRejectionInfo, err error) {
 	log.Infow("execute deal", "uuid", dp.DealUUID)
 
+	// a deal is offline if the client has no intention of pushing data over the wire,
+	// either because it didn't set a transfer type at all or because it explicitly said so.
+	isOffline := dp.IsOffline || dp.Transfer.Type == ""
+
 	ds := types.ProviderDealState{
 		DealUuid:           dp.DealUUID,
 		ClientDealProposal: dp.ClientDealProposal,
@@ -143,6 +213,7 @@ func (p *Provider) ExecuteDeal(dp *types.ClientDealParams) (pi *api.ProviderDeal
 		ClientPeerID:       dp.ClientPeerID,
 		DealDataRoot:       dp.DealDataRoot,
 		Transfer:           dp.Transfer,
+		IsOffline:          isOffline,
 	}
 
 	// validate the deal proposal
@@ -152,19 +223,35 @@ func (p *Provider) ExecuteDeal(dp *types.ClientDealParams) (pi *api.ProviderDeal
 		}, nil
 	}
 
-	// create a temp file where we will hold the deal data.
-	tmp, err := p.fs.CreateTemp()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	if !isOffline {
+		if _, err := p.Transports.TransportFor(dp.Transfer.Type); err != nil {
+			return &api.ProviderDealRejectionInfo{
+				Reason: fmt.Sprintf("unsupported transfer type %q: %s", dp.Transfer.Type, err),
+			}, nil
+		}
 	}
-	if err := tmp.Close(); err != nil {
-		_ = os.Remove(string(tmp.OsPath()))
-		return nil, fmt.Errorf("failed to close temp file: %w", err)
+
+	ds.Checkpoint = dealcheckpoints.Accepted
+
+	if isOffline {
+		// no data is coming over the wire, so there is no temp file to create -
+		// the deal just waits at the Accepted checkpoint until
+		// ImportOfflineDealData is called.
+	} else {
+		// create a temp file where we will hold the deal data.
+		tmp, err := p.fs.CreateTemp()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temp file: %w", err)
+		}
+		if err := tmp.Close(); err != nil {
+			_ = os.Remove(string(tmp.OsPath()))
+			return nil, fmt.Errorf("failed to close temp file: %w", err)
+		}
+		ds.InboundFilePath = string(tmp.OsPath())
 	}
-	ds.InboundFilePath = string(tmp.OsPath())
 	// make sure to remove the temp file if something goes wrong from here on.
 	defer func() {
-		if pi != nil || err != nil {
+		if !isOffline && (pi != nil || err != nil) {
 			_ = os.Remove(ds.InboundFilePath)
 		}
 	}()
@@ -225,10 +312,40 @@ func (p *Provider) Start(ctx context.Context) error {
 
 	go p.transfers.start(p.ctx)
 
+	if err := p.resumeUnfinishedDeals(); err != nil {
+		return fmt.Errorf("failed to resume unfinished deals: %w", err)
+	}
+
 	log.Infow("storage provider: started")
 	return nil
 }
 
+// resumeUnfinishedDeals picks up every deal whose Checkpoint isn't terminal
+// and re-enters the state machine from exactly the stage it was at when the
+// provider last stopped: re-opens the transport for a deal stuck at
+// Transferring, restarts commP for one stuck at Transferred, and so on.
+func (p *Provider) resumeUnfinishedDeals() error {
+	deals, err := p.dealsDB.ListActive(p.ctx)
+	if err != nil {
+		return fmt.Errorf("listing active deals: %w", err)
+	}
+
+	for _, deal := range deals {
+		deal := deal
+		if deal.Checkpoint.IsTerminal() {
+			continue
+		}
+		log.Infow("resuming deal", "id", deal.DealUuid, "checkpoint", deal.Checkpoint)
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			p.runDeal(deal)
+		}()
+	}
+
+	return nil
+}
+
 func (p *Provider) Close() error {
 	p.closeSync.Do(func() {
 		p.cancel()
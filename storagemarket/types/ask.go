@@ -0,0 +1,29 @@
+package types
+
+import (
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/crypto"
+)
+
+// StorageAsk is the current terms under which a provider is willing to
+// accept storage deals.
+type StorageAsk struct {
+	Price         abi.TokenAmount
+	VerifiedPrice abi.TokenAmount
+	MinPieceSize  abi.PaddedPieceSize
+	MaxPieceSize  abi.PaddedPieceSize
+	Miner         address.Address
+	// Expiry is the chain epoch at which this ask is no longer valid.
+	Expiry abi.ChainEpoch
+	// SeqNo increments every time the ask is updated, so stale asks can be
+	// told apart from the current one.
+	SeqNo uint64
+}
+
+// SignedStorageAsk is a StorageAsk signed by the miner's worker key, so that
+// clients can verify the terms they're relying on actually came from the SP.
+type SignedStorageAsk struct {
+	Ask       *StorageAsk
+	Signature *crypto.Signature
+}
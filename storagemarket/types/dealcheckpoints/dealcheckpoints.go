@@ -0,0 +1,65 @@
+// Package dealcheckpoints defines the stages a storage deal passes through
+// on the provider side. Each stage is persisted to the deals DB before the
+// provider moves on to the next one, so that Provider.Start can resume a
+// deal from exactly where it left off after a restart.
+package dealcheckpoints
+
+type Checkpoint int
+
+const (
+	// Accepted means the deal has passed validation and the acceptance
+	// filter, and is ready to receive data (or, for an offline deal, is
+	// waiting on ImportOfflineDealData).
+	Accepted Checkpoint = iota
+	// Transferring means the provider is actively pulling deal data.
+	Transferring
+	// Transferred means all deal data has been received (or imported).
+	Transferred
+	// CommPComputed means the received data has been verified against the
+	// proposal's PieceCID/PieceSize.
+	CommPComputed
+	// Published means a publish-storage-deals message has been sent.
+	Published
+	// PublishConfirmed means the publish message landed on chain.
+	PublishConfirmed
+	// AddedToPiecestore means the piece has been handed off to the sealer.
+	AddedToPiecestore
+	// Sealing means the piece is being sealed into a sector.
+	Sealing
+	// IndexedAndAnnounced means the sector holding the deal's piece has
+	// finished sealing. At this checkpoint the piece is registered with the
+	// retrieval provider, if one is set; nothing is announced to an external
+	// indexer.
+	IndexedAndAnnounced
+	// Complete is a terminal checkpoint: the deal is fully done.
+	Complete
+	// Failed is a terminal checkpoint: the deal will not proceed further.
+	Failed
+)
+
+var names = map[Checkpoint]string{
+	Accepted:            "Accepted",
+	Transferring:        "Transferring",
+	Transferred:         "Transferred",
+	CommPComputed:       "CommPComputed",
+	Published:           "Published",
+	PublishConfirmed:    "PublishConfirmed",
+	AddedToPiecestore:   "AddedToPiecestore",
+	Sealing:             "Sealing",
+	IndexedAndAnnounced: "IndexedAndAnnounced",
+	Complete:            "Complete",
+	Failed:              "Failed",
+}
+
+func (c Checkpoint) String() string {
+	if n, ok := names[c]; ok {
+		return n
+	}
+	return "Unknown"
+}
+
+// IsTerminal returns true if a deal at this checkpoint will never advance
+// any further, and so doesn't need to be resumed on restart.
+func (c Checkpoint) IsTerminal() bool {
+	return c == Complete || c == Failed
+}
@@ -0,0 +1,23 @@
+package dealcheckpoints
+
+import "testing"
+
+func TestCheckpointString(t *testing.T) {
+	for cp, name := range names {
+		if got := cp.String(); got != name {
+			t.Errorf("Checkpoint(%d).String() = %q, want %q", cp, got, name)
+		}
+	}
+	if got := Checkpoint(-1).String(); got != "Unknown" {
+		t.Errorf("unrecognized Checkpoint.String() = %q, want %q", got, "Unknown")
+	}
+}
+
+func TestCheckpointIsTerminal(t *testing.T) {
+	for cp := range names {
+		want := cp == Complete || cp == Failed
+		if got := cp.IsTerminal(); got != want {
+			t.Errorf("Checkpoint(%s).IsTerminal() = %v, want %v", cp, got, want)
+		}
+	}
+}
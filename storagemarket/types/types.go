@@ -0,0 +1,75 @@
+package types
+
+import (
+	"github.com/filecoin-project/go-fil-markets/storagemarket"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/google/uuid"
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p-core/peer"
+
+	"github.com/filecoin-project/boost/storagemarket/types/dealcheckpoints"
+)
+
+// Transfer describes how deal data will move from the client to the provider.
+type Transfer struct {
+	// Type is the name of the transport the client will use to push data,
+	// e.g. "http", "libp2p". An empty Type means the client does not intend
+	// to push data over the wire at all (see ClientDealParams.IsOffline).
+	Type   string
+	Params []byte
+	Size   uint64
+}
+
+// ClientDealParams are the parameters sent by the client when proposing a deal.
+type ClientDealParams struct {
+	DealUUID           uuid.UUID
+	ClientDealProposal storagemarket.ClientDealProposal
+	DealDataRoot       cid.Cid
+	Transfer           Transfer
+	MinerPeerID        peer.ID
+	ClientPeerID       peer.ID
+	// IsOffline indicates the client will not push deal data over the wire.
+	// The provider accepts the proposal and waits for the data to be
+	// imported out of band via Provider.ImportOfflineDealData.
+	IsOffline bool
+}
+
+// ProviderDealState tracks everything boost knows about a deal on the provider side.
+type ProviderDealState struct {
+	DealUuid           uuid.UUID
+	ClientDealProposal storagemarket.ClientDealProposal
+	SelfPeerID         peer.ID
+	ClientPeerID       peer.ID
+	DealDataRoot       cid.Cid
+	Transfer           Transfer
+	InboundFilePath    string
+
+	// Checkpoint is the last stage of the deal's state machine that has been
+	// durably persisted. Provider.Start resumes any deal whose Checkpoint
+	// is not terminal from exactly this stage.
+	Checkpoint dealcheckpoints.Checkpoint
+
+	// IsOffline mirrors ClientDealParams.IsOffline for the lifetime of the deal.
+	// While IsOffline is true and Checkpoint is Accepted, the deal is
+	// waiting on Provider.ImportOfflineDealData.
+	IsOffline bool
+
+	PieceCID  cid.Cid
+	PieceSize abi.PaddedPieceSize
+
+	// SectorNumber and SectorOffset are set once the piece has been added to
+	// a sector, so it can be located again for retrieval or resumption.
+	SectorNumber abi.SectorNumber
+	SectorOffset abi.PaddedPieceSize
+
+	// SectorUpgrade is true if this deal was chosen to be added via a
+	// snap-deal upgrade of an existing CC sector rather than a fresh
+	// AddPiece, in which case UpgradeSectorNumber names that sector.
+	SectorUpgrade       bool
+	UpgradeSectorNumber abi.SectorNumber
+
+	// FailureReason is set when Checkpoint is dealcheckpoints.Failed, to the text
+	// of the error that failed the deal, e.g. "commP of received data does not
+	// match proposal: ..." for a CommP mismatch.
+	FailureReason string
+}
@@ -0,0 +1,46 @@
+package storagemarket
+
+import (
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/boost/storagemarket/types"
+)
+
+// validateDealProposal runs the checks that decide whether a deal proposal is
+// even worth sending to the acceptance filter: does it match our current ask.
+func (p *Provider) validateDealProposal(deal types.ProviderDealState) error {
+	prop := deal.ClientDealProposal.Proposal
+
+	signed, err := p.GetAsk()
+	if err != nil {
+		return xerrors.Errorf("getting current ask: %w", err)
+	}
+	if signed == nil || signed.Ask == nil {
+		return xerrors.Errorf("no ask has been set for this miner yet")
+	}
+
+	return checkAskTerms(signed.Ask, prop.VerifiedDeal, prop.StoragePricePerEpoch, prop.PieceSize)
+}
+
+// checkAskTerms reports an error if a proposal with the given terms falls
+// outside what ask allows.
+func checkAskTerms(ask *types.StorageAsk, verifiedDeal bool, pricePerEpoch abi.TokenAmount, pieceSize abi.PaddedPieceSize) error {
+	price := ask.Price
+	if verifiedDeal {
+		price = ask.VerifiedPrice
+	}
+	if pricePerEpoch.LessThan(price) {
+		return xerrors.Errorf("storage price per epoch %s is below ask price %s", pricePerEpoch, price)
+	}
+
+	if pieceSize < ask.MinPieceSize {
+		return xerrors.Errorf("piece size %d is below minimum piece size %d", pieceSize, ask.MinPieceSize)
+	}
+	if pieceSize > ask.MaxPieceSize {
+		return xerrors.Errorf("piece size %d is above maximum piece size %d", pieceSize, ask.MaxPieceSize)
+	}
+
+	return nil
+}
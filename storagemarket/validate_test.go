@@ -0,0 +1,53 @@
+package storagemarket
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/boost/storagemarket/types"
+)
+
+func testAsk() *types.StorageAsk {
+	return &types.StorageAsk{
+		Price:         abi.NewTokenAmount(10),
+		VerifiedPrice: abi.NewTokenAmount(5),
+		MinPieceSize:  abi.PaddedPieceSize(1 << 10),
+		MaxPieceSize:  abi.PaddedPieceSize(1 << 20),
+	}
+}
+
+func TestCheckAskTermsAccepts(t *testing.T) {
+	ask := testAsk()
+	err := checkAskTerms(ask, false, abi.NewTokenAmount(10), abi.PaddedPieceSize(1<<15))
+	if err != nil {
+		t.Fatalf("expected proposal within ask terms to be accepted, got: %s", err)
+	}
+}
+
+func TestCheckAskTermsRejectsLowPrice(t *testing.T) {
+	ask := testAsk()
+	err := checkAskTerms(ask, false, abi.NewTokenAmount(9), abi.PaddedPieceSize(1<<15))
+	if err == nil {
+		t.Fatal("expected a price below the ask to be rejected")
+	}
+}
+
+func TestCheckAskTermsUsesVerifiedPrice(t *testing.T) {
+	ask := testAsk()
+	// below the unverified price, but satisfies the (lower) verified price.
+	err := checkAskTerms(ask, true, abi.NewTokenAmount(5), abi.PaddedPieceSize(1<<15))
+	if err != nil {
+		t.Fatalf("expected verified deal to be checked against VerifiedPrice, got: %s", err)
+	}
+}
+
+func TestCheckAskTermsRejectsPieceSizeOutOfRange(t *testing.T) {
+	ask := testAsk()
+	if err := checkAskTerms(ask, false, abi.NewTokenAmount(10), abi.PaddedPieceSize(1<<9)); err == nil {
+		t.Fatal("expected a piece smaller than MinPieceSize to be rejected")
+	}
+	if err := checkAskTerms(ask, false, abi.NewTokenAmount(10), abi.PaddedPieceSize(1<<21)); err == nil {
+		t.Fatal("expected a piece larger than MaxPieceSize to be rejected")
+	}
+}
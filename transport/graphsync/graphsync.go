@@ -0,0 +1,130 @@
+// Package graphsync lets boost pull deal data from clients that push over
+// graphsync/data-transfer instead of boost's default HTTP transport, for
+// interop with the go-fil-markets client stack.
+package graphsync
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/filecoin-project/boost/transport"
+	datatransfer "github.com/filecoin-project/go-data-transfer"
+	"github.com/ipfs/go-cid"
+	carv2 "github.com/ipld/go-car/v2"
+	carblockstore "github.com/ipld/go-car/v2/blockstore"
+	selectorparse "github.com/ipld/go-ipld-prime/traversal/selector/parse"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"golang.org/x/xerrors"
+)
+
+// dealParams is the JSON payload carried in ClientDealParams.Transfer.Params
+// for a "graphsync" transfer.
+type dealParams struct {
+	Root   cid.Cid `json:"root"`
+	PeerID peer.ID `json:"peerID"`
+}
+
+// Transport implements transport.Transport by pulling the deal's DAG, rooted
+// at the proposal's DealDataRoot, over graphsync and writing it out as a
+// CARv2 file at destFile.
+//
+// cfg.SupportsResume isn't used by this transport: every call to Execute
+// opens a new pull channel for the whole DAG. cfg.MaxTransferSize IS
+// enforced, but only once the whole DAG has already landed on destFile -
+// the DAG's size isn't known until the transfer finishes, so unlike HTTP's
+// streaming io.LimitReader this doesn't bound resource usage during the
+// pull itself, only whether its result is accepted afterwards.
+type Transport struct {
+	dtManager datatransfer.Manager
+	cfg       transport.Config
+
+	// sem bounds the number of Executes in flight at once, per
+	// cfg.MaxConcurrentTransfers. Nil (unlimited) if that's left unset.
+	sem chan struct{}
+}
+
+func New(dtManager datatransfer.Manager, cfg transport.Config) *Transport {
+	t := &Transport{dtManager: dtManager, cfg: cfg}
+	if cfg.MaxConcurrentTransfers > 0 {
+		t.sem = make(chan struct{}, cfg.MaxConcurrentTransfers)
+	}
+	return t
+}
+
+// Execute pulls the full DAG rooted at params.Root from params.PeerID and
+// writes it into a CARv2 file at destFile.
+func (t *Transport) Execute(ctx context.Context, destFile string, params []byte) (uint64, error) {
+	var dp dealParams
+	if err := json.Unmarshal(params, &dp); err != nil {
+		return 0, xerrors.Errorf("unmarshalling graphsync transfer params: %w", err)
+	}
+
+	if t.sem != nil {
+		select {
+		case t.sem <- struct{}{}:
+			defer func() { <-t.sem }()
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+
+	bs, err := carblockstore.OpenReadWrite(destFile, []cid.Cid{dp.Root}, carv2.ZeroLengthSectionAsEOF(true))
+	if err != nil {
+		return 0, xerrors.Errorf("opening car blockstore at %s: %w", destFile, err)
+	}
+	defer bs.Finalize() // nolint:errcheck
+
+	// pull the entire DAG under the deal's data root - equivalent to the
+	// "all selector" used by lotus/go-fil-markets for storage deal transfers.
+	sel := selectorparse.CommonSelector_ExploreAllRecursively
+
+	voucher := datatransfer.TypedVoucher{}
+	chid, err := t.dtManager.OpenPullDataChannel(ctx, dp.PeerID, voucher, dp.Root, sel)
+	if err != nil {
+		return 0, xerrors.Errorf("opening pull data channel to %s: %w", dp.PeerID, err)
+	}
+
+	if err := waitForCompletion(ctx, t.dtManager, chid); err != nil {
+		return 0, xerrors.Errorf("graphsync transfer %s: %w", chid, err)
+	}
+
+	size, err := bs.Size()
+	if err != nil {
+		return 0, err
+	}
+	if t.cfg.MaxTransferSize > 0 && size > t.cfg.MaxTransferSize {
+		return size, xerrors.Errorf("transfer %s exceeds configured max size of %d bytes", chid, t.cfg.MaxTransferSize)
+	}
+	return size, nil
+}
+
+// Serve is not supported: boost never streams sealed pieces back out over
+// graphsync, only HTTP. Retrieval clients that want graphsync can be added
+// the same way this transport was.
+func (t *Transport) Serve(ctx context.Context, w io.Writer, r io.Reader) (uint64, error) {
+	return 0, xerrors.Errorf("graphsync transport does not support serving data")
+}
+
+func waitForCompletion(ctx context.Context, dtManager datatransfer.Manager, chid datatransfer.ChannelID) error {
+	done := make(chan error, 1)
+	unsub := dtManager.SubscribeToEvents(func(event datatransfer.Event, state datatransfer.ChannelState) {
+		if state.ChannelID() != chid {
+			return
+		}
+		switch state.Status() {
+		case datatransfer.Completed:
+			done <- nil
+		case datatransfer.Failed, datatransfer.Cancelled:
+			done <- xerrors.Errorf("transfer ended with status %s", datatransfer.Statuses[state.Status()])
+		}
+	})
+	defer unsub()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
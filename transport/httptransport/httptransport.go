@@ -0,0 +1,124 @@
+// Package httptransport is boost's default transport: deal data is pulled
+// (and, for retrieval, pushed) over plain HTTP.
+package httptransport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/filecoin-project/boost/transport"
+	"golang.org/x/xerrors"
+)
+
+// dealParams is the JSON payload carried in ClientDealParams.Transfer.Params
+// for an "http" transfer: the URL to pull the deal data from.
+type dealParams struct {
+	URL string `json:"url"`
+}
+
+// Transport implements transport.Transport over HTTP.
+type Transport struct {
+	client *http.Client
+	cfg    transport.Config
+
+	// sem bounds the number of Executes in flight at once, per
+	// cfg.MaxConcurrentTransfers. Nil (unlimited) if that's left unset.
+	sem chan struct{}
+}
+
+func New(cfg transport.Config) *Transport {
+	t := &Transport{client: http.DefaultClient, cfg: cfg}
+	if cfg.MaxConcurrentTransfers > 0 {
+		t.sem = make(chan struct{}, cfg.MaxConcurrentTransfers)
+	}
+	return t
+}
+
+func (t *Transport) Execute(ctx context.Context, destFile string, params []byte) (uint64, error) {
+	var dp dealParams
+	if err := json.Unmarshal(params, &dp); err != nil {
+		return 0, xerrors.Errorf("unmarshalling http transfer params: %w", err)
+	}
+
+	if t.sem != nil {
+		select {
+		case t.sem <- struct{}{}:
+			defer func() { <-t.sem }()
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+
+	// if this transfer supports resuming and a previous attempt left data
+	// behind, pick up from where it stopped instead of starting over.
+	var haveBytes int64
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if t.cfg.SupportsResume {
+		if fi, err := os.Stat(destFile); err == nil {
+			haveBytes = fi.Size()
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, dp.URL, nil)
+	if err != nil {
+		return 0, xerrors.Errorf("building request for %s: %w", dp.URL, err)
+	}
+	if haveBytes > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", haveBytes))
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return 0, xerrors.Errorf("fetching %s: %w", dp.URL, err)
+	}
+	defer resp.Body.Close() // nolint:errcheck
+
+	switch {
+	case haveBytes > 0 && resp.StatusCode == http.StatusPartialContent:
+		flags = os.O_WRONLY | os.O_APPEND
+	case haveBytes > 0 && resp.StatusCode == http.StatusOK:
+		// server ignored our Range request and is sending the whole body
+		// again - fall back to overwriting destFile from scratch.
+		haveBytes = 0
+	case resp.StatusCode != http.StatusOK:
+		return 0, xerrors.Errorf("fetching %s: unexpected status %s", dp.URL, resp.Status)
+	}
+
+	f, err := os.OpenFile(destFile, flags, 0644)
+	if err != nil {
+		return 0, xerrors.Errorf("opening %s: %w", destFile, err)
+	}
+	defer f.Close() // nolint:errcheck
+
+	if t.cfg.MaxTransferSize > 0 && uint64(haveBytes) >= t.cfg.MaxTransferSize {
+		return uint64(haveBytes), xerrors.Errorf("resuming %s: already have %d bytes, which meets or exceeds the configured max size of %d bytes", dp.URL, haveBytes, t.cfg.MaxTransferSize)
+	}
+
+	body := io.Reader(resp.Body)
+	if t.cfg.MaxTransferSize > 0 {
+		// cap at one byte past the limit so an oversized transfer is
+		// detected even when the server's Content-Length can't be trusted.
+		body = io.LimitReader(resp.Body, int64(t.cfg.MaxTransferSize-uint64(haveBytes))+1)
+	}
+
+	n, err := io.Copy(f, body)
+	if err != nil {
+		return uint64(haveBytes) + uint64(n), xerrors.Errorf("writing %s: %w", destFile, err)
+	}
+	total := uint64(haveBytes) + uint64(n)
+	if t.cfg.MaxTransferSize > 0 && total > t.cfg.MaxTransferSize {
+		return total, xerrors.Errorf("transfer of %s exceeds configured max size of %d bytes", dp.URL, t.cfg.MaxTransferSize)
+	}
+	return total, nil
+}
+
+func (t *Transport) Serve(ctx context.Context, w io.Writer, r io.Reader) (uint64, error) {
+	n, err := io.Copy(w, r)
+	if err != nil {
+		return uint64(n), xerrors.Errorf("serving http response: %w", err)
+	}
+	return uint64(n), nil
+}
@@ -0,0 +1,64 @@
+// Package transport abstracts how bytes move between boost and a deal's
+// counterparty, so the storage and retrieval markets don't need to know
+// whether a given deal is pulling data in over HTTP, libp2p or graphsync.
+package transport
+
+import (
+	"context"
+	"io"
+
+	"golang.org/x/xerrors"
+)
+
+// Transport moves deal data for a single transfer type, e.g. "http" or
+// "graphsync". A Provider looks one up from a Registry keyed by
+// ClientDealParams.Transfer.Type.
+type Transport interface {
+	// Execute pulls the transfer's data into destFile, using params to
+	// locate/authenticate the source (interpretation is transport-specific),
+	// and returns the number of bytes written.
+	Execute(ctx context.Context, destFile string, params []byte) (uint64, error)
+
+	// Serve pushes the contents of r to w (e.g. out over an HTTP response or
+	// a libp2p stream) and returns the number of bytes written. Used by the
+	// retrieval side to stream sealed pieces back to clients.
+	Serve(ctx context.Context, w io.Writer, r io.Reader) (uint64, error)
+}
+
+// Config bounds resource usage for a single transport implementation.
+type Config struct {
+	// MaxConcurrentTransfers limits how many transfers of this type may run
+	// at once. Zero means unlimited.
+	MaxConcurrentTransfers int
+	// MaxTransferSize rejects any transfer whose received data exceeds this
+	// many bytes. Zero means unlimited.
+	MaxTransferSize uint64
+	// SupportsResume indicates Execute can be called again on a transfer
+	// that was interrupted partway through and pick up where it left off.
+	SupportsResume bool
+}
+
+// ErrUnsupportedTransferType is returned by a Registry lookup when
+// no transport is registered for the requested type.
+var ErrUnsupportedTransferType = xerrors.New("unsupported transfer type")
+
+// Registry looks up a Transport by its Transfer.Type name, e.g. "http",
+// "libp2p" or "graphsync".
+type Registry struct {
+	transports map[string]Transport
+}
+
+// NewRegistry builds a Registry from a type -> Transport mapping.
+func NewRegistry(transports map[string]Transport) *Registry {
+	return &Registry{transports: transports}
+}
+
+// TransportFor returns the Transport registered for transferType, or
+// ErrUnsupportedTransferType if none is registered.
+func (r *Registry) TransportFor(transferType string) (Transport, error) {
+	t, ok := r.transports[transferType]
+	if !ok {
+		return nil, xerrors.Errorf("%q: %w", transferType, ErrUnsupportedTransferType)
+	}
+	return t, nil
+}